@@ -0,0 +1,49 @@
+package api
+
+import "time"
+
+// DeployHealthPolicy configures how long RunContainerOperation waits for a container to become
+// healthy and what to do if it never does. It's a field on ServiceSpec so it can vary per service:
+// a JVM app may need minutes to warm up, while a static binary is ready in milliseconds.
+type DeployHealthPolicy struct {
+	// StartPeriod is how long a container without a healthcheck must keep running before it's
+	// considered ready. It doesn't delay failure: a container found not running fails the check
+	// at once. Zero means 5s.
+	StartPeriod time.Duration
+	// Timeout is the overall time budget for the container to become healthy before the wait is
+	// abandoned. Zero means 90s.
+	Timeout time.Duration
+	// Retries is how many consecutive failed health checks are tolerated before the container is
+	// declared unhealthy, absorbing a transient blip (e.g. a healthcheck probe that fails once
+	// while a dependency is still coming up). Zero means 1, i.e. any failure ends the wait
+	// immediately.
+	Retries int
+	// FailureAction determines what RunContainerOperation does once the container is declared
+	// unhealthy. Zero value is DeployFailureActionFail.
+	FailureAction DeployFailureAction
+	// MinRunning is the minimum number of a service's replicas that must stay healthy while a
+	// rolling deploy is in progress. RunContainerOperation only ever drives a single container's
+	// health wait and has no view of the rest of the service's replicas, so it doesn't consult
+	// this field; it's read by the planner that decides how many replicas to take down
+	// concurrently (e.g. sizing a ParallelOperation's MaxConcurrency) before building the plan
+	// operations in this package execute.
+	MinRunning int
+}
+
+// DeployFailureAction determines how a failed health check is handled.
+type DeployFailureAction string
+
+const (
+	// DeployFailureActionFail propagates the health-check failure as an error, stopping the
+	// deployment.
+	DeployFailureActionFail DeployFailureAction = "fail"
+	// DeployFailureActionIgnore treats the container as ready anyway and lets the deployment
+	// proceed.
+	DeployFailureActionIgnore DeployFailureAction = "ignore"
+	// DeployFailureActionRollback propagates the failure like DeployFailureActionFail, but tags
+	// it so a containing SequenceOperation compensates the operations that already succeeded
+	// even if the sequence's own RollbackPolicy wouldn't otherwise trigger for this failure (e.g.
+	// RollbackNever). Use this to make one service's health failure always roll back the plan,
+	// regardless of how the rest of the plan is configured.
+	DeployFailureActionRollback DeployFailureAction = "rollback"
+)
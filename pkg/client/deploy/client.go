@@ -0,0 +1,43 @@
+package deploy
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/psviderski/uncloud/pkg/api"
+)
+
+// Client is the machine-scoped set of container and volume operations that the Operation
+// implementations in this package are built against. It's satisfied by the uncloud client that
+// dials a machine's daemon; operations only ever see it through this interface so they stay
+// testable without a real daemon behind them.
+type Client interface {
+	CreateContainer(ctx context.Context, serviceID string, spec api.ServiceSpec, machineID string) (container.CreateResponse, error)
+	StartContainer(ctx context.Context, serviceID, containerID string) error
+	StopContainer(ctx context.Context, serviceID, containerID string, opts container.StopOptions) error
+	RemoveContainer(ctx context.Context, serviceID, containerID string, opts container.RemoveOptions) error
+	InspectContainer(ctx context.Context, serviceID, containerID string) (ContainerInspectResponse, error)
+
+	CreateVolume(ctx context.Context, machineID string, opts volume.CreateOptions) (volume.Volume, error)
+	RemoveVolume(ctx context.Context, machineID, name string, force bool) error
+
+	// ContainerEvents streams Docker events for a container on machineID matching filters, so
+	// waitForContainerHealthy can react to state transitions instead of polling InspectContainer.
+	// It mirrors the Docker SDK's own Events method: the error channel carries at most one error
+	// and is closed once the subscription ends; the message channel isn't guaranteed to close.
+	ContainerEvents(ctx context.Context, machineID string, filters filters.Args) (<-chan events.Message, <-chan error)
+
+	// ContainerLogs returns the last tailLines lines of a container's combined stdout/stderr, for
+	// attaching to crash-loop and health-check failure errors so an operator doesn't have to go
+	// dig them up separately.
+	ContainerLogs(ctx context.Context, serviceID, containerID string, tailLines int) (string, error)
+}
+
+// ContainerInspectResponse is the result of inspecting a container, carrying its current state as
+// known to the machine's daemon.
+type ContainerInspectResponse struct {
+	Container api.ServiceContainer
+}
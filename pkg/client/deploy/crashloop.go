@@ -0,0 +1,47 @@
+package deploy
+
+import (
+	"github.com/psviderski/uncloud/pkg/api"
+)
+
+// DefaultMaxRestarts is the number of additional restarts CrashLoopDetector tolerates within the
+// health-check wait window before declaring a container crash-looping.
+const DefaultMaxRestarts = 2
+
+// CrashLoopDetector notices a container that's flapping up/down/up under its restart policy
+// during the health-check wait. A plain Running check misses this: if the restart policy is
+// always/on-failure, the container can be back up by the time the next inspect lands, so the
+// failure stays hidden until the overall wait times out. CrashLoopDetector instead watches
+// RestartCount climb across inspections and flags it once it rises too fast to be explained by
+// anything other than the container dying and being restarted repeatedly.
+type CrashLoopDetector struct {
+	// MaxRestarts is the number of additional restarts tolerated since the first observation
+	// before the container is declared crash-looping. Zero means DefaultMaxRestarts.
+	MaxRestarts int
+
+	baseline    int
+	baselineSet bool
+}
+
+func (d *CrashLoopDetector) maxRestarts() int {
+	if d.MaxRestarts > 0 {
+		return d.MaxRestarts
+	}
+	return DefaultMaxRestarts
+}
+
+// Check records ctr's current RestartCount and reports whether the container has restarted more
+// than MaxRestarts times since the first call to Check. The first call only establishes the
+// baseline and never reports crash-looping, since a container may already have a non-zero
+// RestartCount from before this operation started watching it.
+//
+// A container that was deliberately stopped (HasBeenManuallyStopped) is never reported as
+// crash-looping, since a rising RestartCount there reflects an operator action, not a failure.
+func (d *CrashLoopDetector) Check(ctr api.ServiceContainer) bool {
+	if !d.baselineSet {
+		d.baseline = ctr.State.RestartCount
+		d.baselineSet = true
+		return false
+	}
+	return !ctr.State.HasBeenManuallyStopped && ctr.State.RestartCount-d.baseline > d.maxRestarts()
+}
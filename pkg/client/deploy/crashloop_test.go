@@ -0,0 +1,56 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/psviderski/uncloud/pkg/api"
+)
+
+func containerWithRestarts(restartCount int, manuallyStopped bool) api.ServiceContainer {
+	var ctr api.ServiceContainer
+	ctr.State.RestartCount = restartCount
+	ctr.State.HasBeenManuallyStopped = manuallyStopped
+	return ctr
+}
+
+func TestCrashLoopDetector_Check(t *testing.T) {
+	t.Run("first call only establishes the baseline", func(t *testing.T) {
+		var d CrashLoopDetector
+		if d.Check(containerWithRestarts(5, false)) {
+			t.Fatal("first call must not report crash-looping, regardless of the starting RestartCount")
+		}
+	})
+
+	t.Run("reports crash-looping once restarts exceed MaxRestarts since the baseline", func(t *testing.T) {
+		d := CrashLoopDetector{MaxRestarts: 2}
+		d.Check(containerWithRestarts(0, false))
+
+		if d.Check(containerWithRestarts(2, false)) {
+			t.Fatal("restart count within MaxRestarts must not report crash-looping")
+		}
+		if !d.Check(containerWithRestarts(3, false)) {
+			t.Fatal("restart count beyond MaxRestarts must report crash-looping")
+		}
+	})
+
+	t.Run("zero value MaxRestarts falls back to DefaultMaxRestarts", func(t *testing.T) {
+		var d CrashLoopDetector
+		d.Check(containerWithRestarts(0, false))
+
+		if d.Check(containerWithRestarts(DefaultMaxRestarts, false)) {
+			t.Fatal("restart count at the default threshold must not yet report crash-looping")
+		}
+		if !d.Check(containerWithRestarts(DefaultMaxRestarts+1, false)) {
+			t.Fatal("restart count past the default threshold must report crash-looping")
+		}
+	})
+
+	t.Run("manually stopped containers are never reported as crash-looping", func(t *testing.T) {
+		d := CrashLoopDetector{MaxRestarts: 1}
+		d.Check(containerWithRestarts(0, false))
+
+		if d.Check(containerWithRestarts(10, true)) {
+			t.Fatal("a manually stopped container must not report crash-looping no matter the restart count")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// machineDockerClient implements the parts of Client that talk to the Docker Engine API directly
+// over a machine's daemon connection, as opposed to the RPC-backed uncloud client methods that
+// proxy through the control plane. It backs ContainerEvents and ContainerLogs.
+type machineDockerClient struct {
+	docker *dockerclient.Client
+}
+
+// ContainerEvents subscribes to the machine's Docker events stream, scoped to filters, via the
+// Docker SDK's own Events call.
+func (c *machineDockerClient) ContainerEvents(
+	ctx context.Context,
+	_ string,
+	filters filters.Args,
+) (<-chan events.Message, <-chan error) {
+	return c.docker.Events(ctx, events.ListOptions{Filters: filters})
+}
+
+// ContainerLogs fetches and demultiplexes the container's last tailLines log lines. The Docker
+// API multiplexes stdout/stderr onto a single stream when the container wasn't created with a
+// TTY, so the raw bytes need stdcopy.StdCopy to come back out as plain text.
+func (c *machineDockerClient) ContainerLogs(
+	ctx context.Context,
+	_ string,
+	containerID string,
+	tailLines int,
+) (string, error) {
+	reader, err := c.docker.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       strconv.Itoa(tailLines),
+	})
+	if err != nil {
+		return "", fmt.Errorf("get container logs: %w", err)
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&buf, &buf, reader); err != nil {
+		return "", fmt.Errorf("read container logs: %w", err)
+	}
+	return buf.String(), nil
+}
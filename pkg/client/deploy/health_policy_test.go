@@ -0,0 +1,90 @@
+package deploy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/psviderski/uncloud/pkg/api"
+)
+
+func TestRunContainerOperation_applyFailureAction_Retries(t *testing.T) {
+	t.Run("tolerates failures below the retry budget", func(t *testing.T) {
+		o := &RunContainerOperation{}
+		policy := defaultHealthPolicy(api.DeployHealthPolicy{Retries: 3})
+
+		for i := 0; i < 2; i++ {
+			ready, err := o.applyFailureAction(errors.New("boom"), policy)
+			if ready || err != nil {
+				t.Fatalf("attempt %d: expected the wait to keep going, got ready=%v err=%v", i, ready, err)
+			}
+		}
+	})
+
+	t.Run("propagates the error once the retry budget is exhausted", func(t *testing.T) {
+		o := &RunContainerOperation{}
+		policy := defaultHealthPolicy(api.DeployHealthPolicy{Retries: 2})
+		wantErr := errors.New("boom")
+
+		o.applyFailureAction(wantErr, policy)
+		ready, err := o.applyFailureAction(wantErr, policy)
+		if ready || !errors.Is(err, wantErr) {
+			t.Fatalf("expected the failure to propagate after exhausting retries, got ready=%v err=%v", ready, err)
+		}
+	})
+
+	t.Run("FailureActionIgnore treats the container as ready once retries are exhausted", func(t *testing.T) {
+		o := &RunContainerOperation{}
+		policy := defaultHealthPolicy(api.DeployHealthPolicy{Retries: 1, FailureAction: api.DeployFailureActionIgnore})
+
+		ready, err := o.applyFailureAction(errors.New("boom"), policy)
+		if !ready || err != nil {
+			t.Fatalf("expected FailureActionIgnore to report ready, got ready=%v err=%v", ready, err)
+		}
+	})
+
+	t.Run("zero value Retries defaults to failing on the first attempt", func(t *testing.T) {
+		o := &RunContainerOperation{}
+		policy := defaultHealthPolicy(api.DeployHealthPolicy{})
+		wantErr := errors.New("boom")
+
+		ready, err := o.applyFailureAction(wantErr, policy)
+		if ready || !errors.Is(err, wantErr) {
+			t.Fatalf("expected the default policy to fail immediately, got ready=%v err=%v", ready, err)
+		}
+	})
+
+	t.Run("FailureActionFail does not tag the error for forced rollback", func(t *testing.T) {
+		o := &RunContainerOperation{}
+		policy := defaultHealthPolicy(api.DeployHealthPolicy{Retries: 1, FailureAction: api.DeployFailureActionFail})
+
+		_, err := o.applyFailureAction(&HealthCheckError{errors.New("boom")}, policy)
+		var healthErr *HealthCheckError
+		if !errors.As(err, &healthErr) || healthErr.forceRollback {
+			t.Fatalf("expected FailureActionFail to leave forceRollback unset, got %+v", healthErr)
+		}
+	})
+
+	t.Run("FailureActionRollback tags the error for forced rollback", func(t *testing.T) {
+		o := &RunContainerOperation{}
+		policy := defaultHealthPolicy(api.DeployHealthPolicy{Retries: 1, FailureAction: api.DeployFailureActionRollback})
+
+		_, err := o.applyFailureAction(&HealthCheckError{errors.New("boom")}, policy)
+		var healthErr *HealthCheckError
+		if !errors.As(err, &healthErr) || !healthErr.forceRollback {
+			t.Fatalf("expected FailureActionRollback to set forceRollback, got %+v", healthErr)
+		}
+	})
+}
+
+func TestSequenceOperation_ShouldRollback_ForceRollbackOverridesPolicy(t *testing.T) {
+	forced := &HealthCheckError{errors.New("boom"), true}
+	plain := &HealthCheckError{errors.New("boom"), false}
+
+	seq := &SequenceOperation{RollbackPolicy: RollbackNever}
+	if !seq.shouldRollback(forced) {
+		t.Fatal("a forceRollback HealthCheckError must trigger rollback even under RollbackNever")
+	}
+	if seq.shouldRollback(plain) {
+		t.Fatal("an untagged HealthCheckError must not trigger rollback under RollbackNever")
+	}
+}
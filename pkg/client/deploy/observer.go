@@ -0,0 +1,196 @@
+package deploy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// OperationID stably identifies one plan execution (a call to Run and everything it fans out to)
+// so the Client calls and daemon log lines it produces can all be tied back to the same deploy,
+// the same way a request ID ties together the log lines for one HTTP request.
+type OperationID string
+
+// NewOperationID generates a fresh OperationID for a plan about to be executed.
+func NewOperationID() OperationID {
+	var b [8]byte
+	// crypto/rand.Read does not fail in practice (it only returns an error if the OS source is
+	// unavailable), and a zero ID is still usable for correlation, so the error is intentionally
+	// discarded here.
+	_, _ = rand.Read(b[:])
+	return OperationID(hex.EncodeToString(b[:]))
+}
+
+type operationIDKey struct{}
+
+// WithOperationID returns a context carrying id, retrievable by OperationIDFromContext.
+func WithOperationID(ctx context.Context, id OperationID) context.Context {
+	return context.WithValue(ctx, operationIDKey{}, id)
+}
+
+// OperationIDFromContext returns the OperationID stored in ctx, or "" if none is set.
+func OperationIDFromContext(ctx context.Context) OperationID {
+	id, _ := ctx.Value(operationIDKey{}).(OperationID)
+	return id
+}
+
+// ensureOperationID returns ctx with an OperationID attached. If ctx already carries one (because
+// an enclosing operation set it, or the caller attached one before calling Run) that one is
+// reused, so every operation in a plan shares a single ID; otherwise a fresh one is generated.
+//
+// This says nothing about who's responsible for closing that ID's Watch channel: whether a given
+// Execute call is "the root" can't be inferred from ctx, since a pre-attached ID and a
+// propagated-from-a-parent ID look identical here. That responsibility belongs solely to Run.
+func ensureOperationID(ctx context.Context) (newCtx context.Context, id OperationID) {
+	if id := OperationIDFromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id = NewOperationID()
+	return WithOperationID(ctx, id), id
+}
+
+// Run executes a plan's root operation and guarantees the Watch channel for its OperationID is
+// closed once it returns, regardless of how deeply the operation tree nests. It's the one place
+// that owns this lifecycle: individual Execute methods only ever ensure an ID is present on ctx,
+// they never decide whether they're "the root", since that can't be inferred from ctx alone (see
+// ensureOperationID). Call this instead of op.Execute directly to run a plan.
+func Run(ctx context.Context, op Operation, cli Client) error {
+	ctx, id := ensureOperationID(ctx)
+	defer closeWatcher(id)
+	return op.Execute(ctx, cli)
+}
+
+// OperationObserver receives lifecycle notifications for operations executed with an observer
+// registered in their context via WithObserver, letting CLI/UI callers render live progress
+// instead of the plan appearing to hang until it returns or times out.
+type OperationObserver interface {
+	OnStart(op Operation)
+	OnProgress(op Operation, msg string)
+	OnComplete(op Operation, err error)
+}
+
+type observerKey struct{}
+
+// WithObserver returns a context that delivers operation lifecycle notifications to observer.
+func WithObserver(ctx context.Context, observer OperationObserver) context.Context {
+	return context.WithValue(ctx, observerKey{}, observer)
+}
+
+func observerFromContext(ctx context.Context) OperationObserver {
+	observer, _ := ctx.Value(observerKey{}).(OperationObserver)
+	return observer
+}
+
+// notifyStart, notifyProgress and notifyComplete deliver lifecycle notifications for op to both
+// the OperationObserver registered in ctx (if any) and the Watch channel for ctx's OperationID
+// (if anyone is watching it). Operations call these around their own work; they're no-ops when
+// nothing is observing.
+
+func notifyStart(ctx context.Context, op Operation) {
+	if observer := observerFromContext(ctx); observer != nil {
+		observer.OnStart(op)
+	}
+	broadcast(ctx, Event{OperationID: OperationIDFromContext(ctx), Operation: op, Kind: EventStart})
+}
+
+func notifyProgress(ctx context.Context, op Operation, msg string) {
+	if observer := observerFromContext(ctx); observer != nil {
+		observer.OnProgress(op, msg)
+	}
+	broadcast(ctx, Event{
+		OperationID: OperationIDFromContext(ctx),
+		Operation:   op,
+		Kind:        EventProgress,
+		Message:     msg,
+	})
+}
+
+func notifyComplete(ctx context.Context, op Operation, err error) {
+	if observer := observerFromContext(ctx); observer != nil {
+		observer.OnComplete(op, err)
+	}
+	broadcast(ctx, Event{OperationID: OperationIDFromContext(ctx), Operation: op, Kind: EventComplete, Err: err})
+}
+
+// EventKind is the kind of lifecycle notification an Event carries.
+type EventKind int
+
+const (
+	EventStart EventKind = iota
+	EventProgress
+	EventComplete
+)
+
+// Event is a single lifecycle notification for an operation within a watched plan.
+type Event struct {
+	OperationID OperationID
+	Operation   Operation
+	Kind        EventKind
+	// Message carries details for EventProgress, e.g. "health check: running (elapsed 2s)".
+	Message string
+	// Err is set on EventComplete if the operation failed.
+	Err error
+}
+
+var (
+	watchersMu sync.Mutex
+	watchers   = map[OperationID]chan Event{}
+)
+
+// Watch returns a channel of Events for the plan identified by planID. Attach the same planID to
+// the context passed to Run (not Execute directly - see Run) so the events it emits are routed
+// here:
+//
+//	planID := deploy.NewOperationID()
+//	ctx = deploy.WithOperationID(ctx, planID)
+//	events := deploy.Watch(ctx, planID)
+//	go render(events)
+//	err := deploy.Run(ctx, plan, cli)
+//
+// The channel is closed once that call to Run returns.
+func Watch(_ context.Context, planID OperationID) <-chan Event {
+	ch := make(chan Event, 64)
+
+	watchersMu.Lock()
+	watchers[planID] = ch
+	watchersMu.Unlock()
+
+	return ch
+}
+
+// broadcast delivers ev to the channel registered for ev.OperationID via Watch, if any.
+func broadcast(ctx context.Context, ev Event) {
+	id := OperationIDFromContext(ctx)
+	if id == "" {
+		return
+	}
+
+	watchersMu.Lock()
+	ch, ok := watchers[id]
+	watchersMu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case ch <- ev:
+	default:
+		// A slow or absent consumer must not block the deploy; the event is dropped.
+	}
+}
+
+// closeWatcher closes and deregisters the Watch channel for id, if one is registered. Called by
+// Run once the plan's root operation returns.
+func closeWatcher(id OperationID) {
+	watchersMu.Lock()
+	ch, ok := watchers[id]
+	if ok {
+		delete(watchers, id)
+	}
+	watchersMu.Unlock()
+
+	if ok {
+		close(ch)
+	}
+}
@@ -0,0 +1,39 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRun_ClosesWatchChannelOnNestedCompletion(t *testing.T) {
+	planID := NewOperationID()
+	ctx := WithOperationID(context.Background(), planID)
+	events := Watch(ctx, planID)
+
+	// A nested plan (Sequence{Parallel{...}}) so every Execute call along the way sees an
+	// OperationID already on ctx, so none of them can tell it's the root; only Run closes the
+	// channel.
+	inner := &fakeOperation{name: "inner", log: &[]string{}}
+	plan := &SequenceOperation{Operations: []Operation{
+		&ParallelOperation{Operations: []Operation{inner}},
+	}}
+
+	if err := Run(ctx, plan, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		for range events {
+			// Drain whatever was buffered; we only care that the channel eventually closes.
+		}
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Watch channel was not closed after Run returned")
+	}
+}
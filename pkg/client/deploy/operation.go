@@ -2,11 +2,14 @@ package deploy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/psviderski/uncloud/pkg/api"
 )
@@ -30,6 +33,35 @@ type NameResolver interface {
 	ContainerName(containerID string) string
 }
 
+// CompensatingOperation is implemented by operations that can undo their own effect.
+// SequenceOperation uses it to build a rollback plan for operations it has already executed.
+type CompensatingOperation interface {
+	Operation
+	// Compensate returns the operation that reverses the effect of this operation. It may
+	// return nil if the operation hasn't executed far enough to know what needs undoing.
+	Compensate() Operation
+}
+
+// HealthCheckError indicates that an operation failed because a container did not become
+// healthy within its configured wait window, as opposed to e.g. a Docker API error. It lets
+// SequenceOperation distinguish "the deploy is unhealthy" failures from other kinds of errors
+// when applying RollbackOnHealthFailure.
+type HealthCheckError struct {
+	err error
+	// forceRollback is set by applyFailureAction when the failing operation's policy is
+	// api.DeployFailureActionRollback, so shouldRollback compensates the sequence even under a
+	// RollbackPolicy that wouldn't otherwise trigger for this failure.
+	forceRollback bool
+}
+
+func (e *HealthCheckError) Error() string {
+	return e.err.Error()
+}
+
+func (e *HealthCheckError) Unwrap() error {
+	return e.err
+}
+
 // TODO: pass api.ServiceContainer to operations to simplify operation formatting in the plan.
 
 // RunContainerOperation creates and starts a new container on a specific machine.
@@ -37,9 +69,26 @@ type RunContainerOperation struct {
 	ServiceID string
 	Spec      api.ServiceSpec
 	MachineID string
+	// CrashLoop detects a container that's flapping up/down/up under its restart policy during
+	// the health-check wait, which would otherwise look like "still starting" to a Running check.
+	// Its zero value applies CrashLoopDetector's default MaxRestarts.
+	CrashLoop CrashLoopDetector
+
+	// container is the last inspected state of the created container, captured while waiting
+	// for it to become healthy. It's used by Compensate to build the rollback operation and is
+	// nil until the container has been inspected at least once.
+	container *api.ServiceContainer
+	// healthFailures counts consecutive failed health checks, reset implicitly on success since
+	// the wait loop returns as soon as the container is ready. Compared against
+	// policy.Retries by applyFailureAction before a failure actually ends the wait.
+	healthFailures int
 }
 
-func (o *RunContainerOperation) Execute(ctx context.Context, cli Client) error {
+func (o *RunContainerOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
 	resp, err := cli.CreateContainer(ctx, o.ServiceID, o.Spec, o.MachineID)
 	if err != nil {
 		return fmt.Errorf("create container: %w", err)
@@ -67,110 +116,279 @@ func (o *RunContainerOperation) String() string {
 		o.MachineID, o.ServiceID, o.Spec.Container.Image)
 }
 
+// Compensate returns the operation that removes the container created by this operation.
+// It returns nil if the container hasn't been inspected yet, which can happen if Execute
+// failed before the container was ever observed running.
+func (o *RunContainerOperation) Compensate() Operation {
+	if o.container == nil {
+		return nil
+	}
+	return &RemoveContainerOperation{
+		MachineID: o.MachineID,
+		Container: *o.container,
+	}
+}
+
+// defaultHealthPolicy fills in the defaults for any DeployHealthPolicy field left at its zero
+// value, preserving the previous hardcoded behavior (90s timeout, 5s start period, fail on the
+// first definitive failure) for specs that don't configure one explicitly.
+func defaultHealthPolicy(policy api.DeployHealthPolicy) api.DeployHealthPolicy {
+	if policy.Timeout <= 0 {
+		policy.Timeout = 90 * time.Second
+	}
+	if policy.StartPeriod <= 0 {
+		policy.StartPeriod = 5 * time.Second
+	}
+	if policy.Retries <= 0 {
+		policy.Retries = 1
+	}
+	return policy
+}
+
 // waitForContainerHealthy waits for a container to pass its healthcheck before returning.
-// For containers without a healthcheck, it waits briefly to ensure the container doesn't crash immediately.
-// This enables zero-downtime deployments by ensuring new containers are ready before old ones are stopped.
+// For containers without a healthcheck, it waits out policy.StartPeriod to ensure the container
+// doesn't crash immediately. This enables zero-downtime deployments by ensuring new containers
+// are ready before old ones are stopped.
+//
+// Rather than polling InspectContainer on a ticker, it subscribes to the container's Docker
+// events and only re-inspects when an event suggests the state may have changed (start, die,
+// health_status: *), falling back to a plain inspect if the events stream drops or on first
+// attach, so an already-healthy container is detected immediately instead of on the next tick.
 func (o *RunContainerOperation) waitForContainerHealthy(
 	ctx context.Context,
 	cli Client,
 	containerID string,
 ) error {
-	const (
-		pollInterval         = 2 * time.Second
-		maxWaitTime          = 90 * time.Second
-		noHealthcheckWaitFor = 5 * time.Second
-	)
+	policy := defaultHealthPolicy(o.Spec.HealthPolicy)
 
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
 
-	timeout := time.After(maxWaitTime)
 	startTime := time.Now()
 
+	// Inspect once up front to catch containers that are already healthy (or already dead) by
+	// the time we subscribe, and to seed o.container in case we need to compensate later.
+	mc, err := cli.InspectContainer(ctx, o.ServiceID, containerID)
+	if err != nil {
+		return fmt.Errorf("inspect container: %w", err)
+	}
+	o.container = &mc.Container
+	notifyProgress(ctx, o, fmt.Sprintf("health check: %s (elapsed %s)", mc.Container.State.Status, time.Since(startTime).Round(time.Second)))
+	if ready, err := o.evaluateInspectedContainer(ctx, cli, mc.Container, startTime, policy); err != nil || ready {
+		return err
+	}
+
+	eventFilters := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("container", containerID),
+	)
+	msgs, errs := cli.ContainerEvents(ctx, o.MachineID, eventFilters)
+
+	noHealthcheckTimer := time.NewTimer(policy.StartPeriod)
+	defer noHealthcheckTimer.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// The wait can't go on past the timeout, so no retry budget is left to absorb it:
+				// it goes straight to policy.FailureAction.
+				o.healthFailures = policy.Retries
+				ready, err := o.applyFailureAction(&HealthCheckError{fmt.Errorf(
+					"timeout waiting for container %s to become healthy after %s",
+					containerID[:12],
+					policy.Timeout,
+				)}, policy)
+				if ready {
+					return nil
+				}
+				return err
+			}
 			return ctx.Err()
 
-		case <-timeout:
-			return fmt.Errorf(
-				"timeout waiting for container %s to become healthy after %s",
-				containerID[:12],
-				maxWaitTime,
-			)
-
-		case <-ticker.C:
-			// Inspect container to check its current state and health
-			mc, err := cli.InspectContainer(ctx, o.ServiceID, containerID)
-			if err != nil {
-				return fmt.Errorf("inspect container: %w", err)
+		case <-noHealthcheckTimer.C:
+			ready, err := o.reinspectAndCheck(ctx, cli, containerID, startTime, policy)
+			if err != nil || ready {
+				return err
 			}
 
-			ctr := mc.Container
+		case err, ok := <-errs:
+			if !ok {
+				// Channel closed without an error; nothing left to read from it.
+				errs = nil
+				continue
+			}
+			// The events stream dropped. Fall back to a single inspect rather than failing
+			// outright, since the container may already have reached its final state, then
+			// resubscribe to keep waiting.
+			ready, inspectErr := o.reinspectAndCheck(ctx, cli, containerID, startTime, policy)
+			if inspectErr != nil {
+				return fmt.Errorf("inspect container after events stream error (%s): %w", err, inspectErr)
+			}
+			if ready {
+				return nil
+			}
+			msgs, errs = cli.ContainerEvents(ctx, o.MachineID, eventFilters)
 
-			// Check if container is still running
-			if !ctr.State.Running {
-				return fmt.Errorf(
-					"container %s exited during healthcheck wait (status: %s, exit code: %d)",
-					containerID[:12],
-					ctr.State.Status,
-					ctr.State.ExitCode,
-				)
+		case msg, ok := <-msgs:
+			if !ok {
+				msgs = nil
+				continue
 			}
 
-			// Handle containers without healthcheck
-			if ctr.State.Health == nil {
-				// Wait a minimum time to catch immediate crashes
-				if time.Since(startTime) < noHealthcheckWaitFor {
-					continue
+			switch msg.Action {
+			case events.ActionDie, events.ActionHealthStatusHealthy, events.ActionHealthStatusUnhealthy, events.ActionStart:
+				ready, err := o.reinspectAndCheck(ctx, cli, containerID, startTime, policy)
+				if err != nil || ready {
+					return err
 				}
+			}
+		}
+	}
+}
 
-				// Verify container is still running after the wait period
-				mc, err = cli.InspectContainer(ctx, o.ServiceID, containerID)
-				if err != nil {
-					return fmt.Errorf("inspect container after no-healthcheck wait: %w", err)
-				}
+// reinspectAndCheck re-inspects the container and evaluates its health, updating o.container with
+// the freshly observed state.
+func (o *RunContainerOperation) reinspectAndCheck(
+	ctx context.Context,
+	cli Client,
+	containerID string,
+	startTime time.Time,
+	policy api.DeployHealthPolicy,
+) (ready bool, err error) {
+	mc, err := cli.InspectContainer(ctx, o.ServiceID, containerID)
+	if err != nil {
+		return false, fmt.Errorf("inspect container: %w", err)
+	}
+	o.container = &mc.Container
+	notifyProgress(ctx, o, fmt.Sprintf("health check: %s (elapsed %s)", mc.Container.State.Status, time.Since(startTime).Round(time.Second)))
+	return o.evaluateInspectedContainer(ctx, cli, mc.Container, startTime, policy)
+}
 
-				if !mc.Container.State.Running {
-					return fmt.Errorf(
-						"container %s exited shortly after start (status: %s, exit code: %d)",
-						containerID[:12],
-						mc.Container.State.Status,
-						mc.Container.State.ExitCode,
-					)
-				}
+// evaluateInspectedContainer runs the crash-loop check before the regular health evaluation, so a
+// container whose restart policy is masking a failure (Running keeps reading true between
+// restarts) is caught instead of being waited out until the overall timeout.
+func (o *RunContainerOperation) evaluateInspectedContainer(
+	ctx context.Context,
+	cli Client,
+	ctr api.ServiceContainer,
+	startTime time.Time,
+	policy api.DeployHealthPolicy,
+) (ready bool, err error) {
+	if crashErr := o.checkCrashLoop(ctx, cli, ctr); crashErr != nil {
+		return o.applyFailureAction(crashErr, policy)
+	}
+	return o.evaluateHealth(ctr, startTime, policy)
+}
 
-				// No healthcheck, container is running - consider it ready
-				return nil
-			}
+// checkCrashLoop reports a HealthCheckError, with the container's recent logs attached, if
+// o.CrashLoop determines ctr is crash-looping. It returns nil otherwise.
+func (o *RunContainerOperation) checkCrashLoop(ctx context.Context, cli Client, ctr api.ServiceContainer) error {
+	if !o.CrashLoop.Check(ctr) {
+		return nil
+	}
 
-			// Use the existing Healthy() method to check healthcheck status
-			if ctr.Healthy() {
-				// Container is healthy and ready to serve traffic
-				return nil
-			}
+	const logTailLines = 20
+	msg := fmt.Sprintf("container %s is crash-looping (restarted %d times)", ctr.ShortID(), ctr.State.RestartCount)
+	if logs, logsErr := cli.ContainerLogs(ctx, o.ServiceID, ctr.ID, logTailLines); logsErr == nil {
+		msg += fmt.Sprintf("\n--- last %d lines of logs ---\n%s", logTailLines, logs)
+	}
 
-			// Check if explicitly unhealthy (not just starting)
-			if ctr.State.Health.Status == container.Unhealthy {
-				// Get last healthcheck log for error message
-				lastLog := "no healthcheck logs available"
-				if len(ctr.State.Health.Log) > 0 {
-					lastLog = strings.TrimSpace(ctr.State.Health.Log[len(ctr.State.Health.Log)-1].Output)
-					if len(lastLog) > 200 {
-						lastLog = lastLog[:200] + "..."
-					}
-				}
-				return fmt.Errorf(
-					"container %s became unhealthy: %s",
-					containerID[:12],
-					lastLog,
-				)
-			}
+	return &HealthCheckError{errors.New(msg)}
+}
 
-			// Still starting or unknown status - continue waiting
-			continue
+// evaluateHealth checks a single snapshot of container state against policy and reports whether
+// the container is ready to serve traffic. A definitive failure (exited, or explicitly
+// unhealthy) is run through applyFailureAction so policy.FailureAction can downgrade it.
+func (o *RunContainerOperation) evaluateHealth(
+	ctr api.ServiceContainer,
+	startTime time.Time,
+	policy api.DeployHealthPolicy,
+) (ready bool, err error) {
+	ready, err = o.checkContainerHealth(ctr, startTime, policy)
+	if err == nil {
+		return ready, nil
+	}
+	return o.applyFailureAction(err, policy)
+}
+
+// applyFailureAction tolerates up to policy.Retries consecutive definitive health-check failures
+// before doing anything about one, so a single flaky check (e.g. a probe that fails once while a
+// dependency is still coming up) doesn't end the wait early. Once the retry budget is used up, it
+// applies policy.FailureAction: FailureActionIgnore treats the container as ready anyway so the
+// deploy proceeds; FailureActionFail propagates err unchanged; FailureActionRollback also
+// propagates err unchanged but tags it so shouldRollback compensates the sequence even if its
+// RollbackPolicy wouldn't otherwise trigger for this failure.
+func (o *RunContainerOperation) applyFailureAction(
+	err error,
+	policy api.DeployHealthPolicy,
+) (ready bool, out error) {
+	o.healthFailures++
+	if o.healthFailures < policy.Retries {
+		return false, nil
+	}
+
+	switch policy.FailureAction {
+	case api.DeployFailureActionIgnore:
+		return true, nil
+	case api.DeployFailureActionRollback:
+		if healthErr, ok := err.(*HealthCheckError); ok {
+			healthErr.forceRollback = true
+		}
+	}
+	return false, err
+}
+
+// checkContainerHealth inspects a single snapshot of container state and reports whether the
+// container is ready to serve traffic. It returns a HealthCheckError if the container has
+// definitively failed (exited, or explicitly unhealthy), or (false, nil) if it's still starting.
+func (o *RunContainerOperation) checkContainerHealth(
+	ctr api.ServiceContainer,
+	startTime time.Time,
+	policy api.DeployHealthPolicy,
+) (ready bool, err error) {
+	// Check if container is still running.
+	if !ctr.State.Running {
+		return false, &HealthCheckError{fmt.Errorf(
+			"container %s exited during healthcheck wait (status: %s, exit code: %d)",
+			ctr.ShortID(),
+			ctr.State.Status,
+			ctr.State.ExitCode,
+		)}
+	}
+
+	// Handle containers without healthcheck.
+	if ctr.State.Health == nil {
+		// Wait out the start period to catch immediate crashes, then consider it ready.
+		if time.Since(startTime) < policy.StartPeriod {
+			return false, nil
+		}
+		return true, nil
+	}
+
+	// Use the existing Healthy() method to check healthcheck status.
+	if ctr.Healthy() {
+		return true, nil
+	}
+
+	// Check if explicitly unhealthy (not just starting).
+	if ctr.State.Health.Status == container.Unhealthy {
+		lastLog := "no healthcheck logs available"
+		if len(ctr.State.Health.Log) > 0 {
+			lastLog = strings.TrimSpace(ctr.State.Health.Log[len(ctr.State.Health.Log)-1].Output)
+			if len(lastLog) > 200 {
+				lastLog = lastLog[:200] + "..."
+			}
 		}
+		return false, &HealthCheckError{fmt.Errorf(
+			"container %s became unhealthy: %s",
+			ctr.ShortID(),
+			lastLog,
+		)}
 	}
+
+	// Still starting or unknown status - continue waiting.
+	return false, nil
 }
 
 // StopContainerOperation stops a container on a specific machine.
@@ -180,7 +398,11 @@ type StopContainerOperation struct {
 	MachineID   string
 }
 
-func (o *StopContainerOperation) Execute(ctx context.Context, cli Client) error {
+func (o *StopContainerOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
 	if err := cli.StopContainer(ctx, o.ServiceID, o.ContainerID, container.StopOptions{}); err != nil {
 		return fmt.Errorf("stop container: %w", err)
 	}
@@ -198,13 +420,56 @@ func (o *StopContainerOperation) String() string {
 		o.MachineID, o.ServiceID, o.ContainerID)
 }
 
+// Compensate returns the operation that starts the container back up.
+func (o *StopContainerOperation) Compensate() Operation {
+	return &StartContainerOperation{
+		ServiceID:   o.ServiceID,
+		ContainerID: o.ContainerID,
+		MachineID:   o.MachineID,
+	}
+}
+
+// StartContainerOperation starts a previously stopped container on a specific machine.
+// It exists primarily as the compensating operation for StopContainerOperation during rollback.
+type StartContainerOperation struct {
+	ServiceID   string
+	ContainerID string
+	MachineID   string
+}
+
+func (o *StartContainerOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
+	if err := cli.StartContainer(ctx, o.ServiceID, o.ContainerID); err != nil {
+		return fmt.Errorf("start container: %w", err)
+	}
+	return nil
+}
+
+func (o *StartContainerOperation) Format(resolver NameResolver) string {
+	machineName := resolver.MachineName(o.MachineID)
+	return fmt.Sprintf("%s: Start container [id=%s name=%s]", machineName,
+		o.ContainerID[:12], resolver.ContainerName(o.ContainerID))
+}
+
+func (o *StartContainerOperation) String() string {
+	return fmt.Sprintf("StartContainerOperation[machine_id=%s service_id=%s container_id=%s]",
+		o.MachineID, o.ServiceID, o.ContainerID)
+}
+
 // RemoveContainerOperation stops and removes a container from a specific machine.
 type RemoveContainerOperation struct {
 	MachineID string
 	Container api.ServiceContainer
 }
 
-func (o *RemoveContainerOperation) Execute(ctx context.Context, cli Client) error {
+func (o *RemoveContainerOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
 	if err := cli.StopContainer(ctx, o.Container.ServiceID(), o.Container.ID, container.StopOptions{}); err != nil {
 		return fmt.Errorf("stop container: %w", err)
 	}
@@ -237,7 +502,11 @@ type CreateVolumeOperation struct {
 	MachineName string
 }
 
-func (o *CreateVolumeOperation) Execute(ctx context.Context, cli Client) error {
+func (o *CreateVolumeOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
 	if o.VolumeSpec.Type != api.VolumeTypeVolume {
 		return fmt.Errorf("invalid volume type: '%s', expected '%s'", o.VolumeSpec.Type, api.VolumeTypeVolume)
 	}
@@ -269,27 +538,191 @@ func (o *CreateVolumeOperation) String() string {
 		o.MachineID, o.VolumeSpec.DockerVolumeName())
 }
 
+// Compensate returns the operation that removes the volume.
+func (o *CreateVolumeOperation) Compensate() Operation {
+	return &RemoveVolumeOperation{
+		VolumeSpec:  o.VolumeSpec,
+		MachineID:   o.MachineID,
+		MachineName: o.MachineName,
+	}
+}
+
+// RemoveVolumeOperation removes a volume from a specific machine. It exists primarily as the
+// compensating operation for CreateVolumeOperation during rollback.
+type RemoveVolumeOperation struct {
+	VolumeSpec api.VolumeSpec
+	MachineID  string
+	// MachineName is used for formatting the operation output only.
+	MachineName string
+}
+
+func (o *RemoveVolumeOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
+	if err := cli.RemoveVolume(ctx, o.MachineID, o.VolumeSpec.DockerVolumeName(), true); err != nil {
+		return fmt.Errorf("remove volume: %w", err)
+	}
+	return nil
+}
+
+func (o *RemoveVolumeOperation) Format(_ NameResolver) string {
+	return fmt.Sprintf("%s: Remove volume [name=%s]", o.MachineName, o.VolumeSpec.DockerVolumeName())
+}
+
+func (o *RemoveVolumeOperation) String() string {
+	return fmt.Sprintf("RemoveVolumeOperation[machine_id=%s volume=%s]",
+		o.MachineID, o.VolumeSpec.DockerVolumeName())
+}
+
+// RollbackPolicy determines whether a SequenceOperation compensates for its successfully
+// executed operations when a later operation in the sequence fails.
+type RollbackPolicy int
+
+const (
+	// RollbackNever never rolls back, leaving the deployment in whatever state it reached
+	// before the failure. This is the zero value and matches the pre-existing behavior.
+	RollbackNever RollbackPolicy = iota
+	// RollbackAlways rolls back on any operation failure.
+	RollbackAlways
+	// RollbackOnHealthFailure rolls back only when the failure is a HealthCheckError, i.e. a
+	// container never became healthy. Other failures (e.g. a Docker API error) are left as is.
+	RollbackOnHealthFailure
+)
+
+func (p RollbackPolicy) String() string {
+	switch p {
+	case RollbackAlways:
+		return "always"
+	case RollbackOnHealthFailure:
+		return "on-health-failure"
+	default:
+		return "never"
+	}
+}
+
 // SequenceOperation is a composite operation that executes a sequence of operations in order.
+// If RollbackPolicy requires it, a failure partway through triggers compensation of every
+// operation that already succeeded, plus the one that just failed if it knows how to undo
+// whatever partial effect it had, turning a partial failure into an all-or-nothing deploy.
 type SequenceOperation struct {
-	Operations []Operation
+	Operations     []Operation
+	RollbackPolicy RollbackPolicy
 }
 
-func (o *SequenceOperation) Execute(ctx context.Context, cli Client) error {
+func (o *SequenceOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
+	executed := make([]Operation, 0, len(o.Operations))
 	for _, op := range o.Operations {
 		if err := op.Execute(ctx, cli); err != nil {
+			// The operation that just failed may itself need compensating: e.g. a
+			// RunContainerOperation that created and started a container before failing its
+			// health check hasn't "succeeded", but Compensate() on it is specifically written to
+			// handle this case (it returns nil if the op never got far enough to need undoing).
+			rollbackOps := executed
+			if _, ok := op.(CompensatingOperation); ok {
+				rollbackOps = append(rollbackOps, op)
+			}
+
+			if o.shouldRollback(err) {
+				if rollbackErr := rollback(ctx, cli, rollbackOps); rollbackErr != nil {
+					return fmt.Errorf("%w (rollback failed: %s)", err, rollbackErr)
+				}
+			}
 			return err
 		}
+		executed = append(executed, op)
 	}
 	return nil
 }
 
+// shouldRollback reports whether err, returned by an operation in the sequence, warrants
+// rolling back the operations that already succeeded under o.RollbackPolicy.
+func (o *SequenceOperation) shouldRollback(err error) bool {
+	var healthErr *HealthCheckError
+	isHealthErr := errors.As(err, &healthErr)
+
+	// A HealthCheckError tagged forceRollback (DeployFailureActionRollback on the failing
+	// operation) compensates the sequence regardless of RollbackPolicy.
+	if isHealthErr && healthErr.forceRollback {
+		return true
+	}
+
+	switch o.RollbackPolicy {
+	case RollbackAlways:
+		return true
+	case RollbackOnHealthFailure:
+		return isHealthErr
+	default:
+		return false
+	}
+}
+
+// rollback walks executed operations in reverse, invoking the compensating action of each one
+// that supports it. It keeps going on a compensation failure so a single operation that can't
+// be undone doesn't leave the rest of the stack untouched, and returns the first error seen.
+func rollback(ctx context.Context, cli Client, executed []Operation) error {
+	var firstErr error
+	for i := len(executed) - 1; i >= 0; i-- {
+		comp, ok := executed[i].(CompensatingOperation)
+		if !ok {
+			// Silently leaving this one alone would turn a partial-failure deploy back into the
+			// "broken half-state" rollback is supposed to prevent, so surface it instead.
+			if firstErr == nil {
+				firstErr = fmt.Errorf("rollback incomplete: %s does not support compensation", executed[i])
+			}
+			continue
+		}
+
+		inverse := comp.Compensate()
+		if inverse == nil {
+			continue
+		}
+
+		if err := inverse.Execute(ctx, cli); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("compensate %s: %w", executed[i], err)
+		}
+	}
+	return firstErr
+}
+
 func (o *SequenceOperation) Format(resolver NameResolver) string {
 	ops := make([]string, len(o.Operations))
 	for i, op := range o.Operations {
-		ops[i] = "- " + op.Format(resolver)
+		ops[i] = formatOperation(op, resolver)
+	}
+	plan := strings.Join(ops, "\n")
+
+	rollbackPlan := o.rollbackPlan(resolver)
+	if rollbackPlan == "" {
+		return plan
 	}
+	return fmt.Sprintf("%s\n\nRollback plan (policy=%s):\n%s", plan, o.RollbackPolicy, rollbackPlan)
+}
 
-	return strings.Join(ops, "\n")
+// rollbackPlan previews the compensating actions for operations that can produce one without
+// having executed, so users can see what an eventual rollback would do before running the plan.
+// Operations whose compensation can only be determined at runtime (e.g. RunContainerOperation,
+// which needs the ID Docker assigns on creation) are omitted from the preview.
+func (o *SequenceOperation) rollbackPlan(resolver NameResolver) string {
+	var lines []string
+	for i := len(o.Operations) - 1; i >= 0; i-- {
+		comp, ok := o.Operations[i].(CompensatingOperation)
+		if !ok {
+			continue
+		}
+
+		inverse := comp.Compensate()
+		if inverse == nil {
+			continue
+		}
+		lines = append(lines, formatOperation(inverse, resolver))
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (o *SequenceOperation) String() string {
@@ -300,3 +733,14 @@ func (o *SequenceOperation) String() string {
 
 	return fmt.Sprintf("SequenceOperation[%s]", strings.Join(ops, ", "))
 }
+
+// formatOperation renders op as a single bulleted list item. If op is a composite operation
+// whose own Format spans multiple lines (SequenceOperation, ParallelOperation), the continuation
+// lines are indented so they visually nest under the bullet that introduces them.
+func formatOperation(op Operation, resolver NameResolver) string {
+	lines := strings.Split(op.Format(resolver), "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = "  " + lines[i]
+	}
+	return "- " + strings.Join(lines, "\n")
+}
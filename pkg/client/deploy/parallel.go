@@ -0,0 +1,125 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// ParallelOperation is a composite operation that executes its child operations concurrently,
+// bounded by MaxConcurrency and optionally throttled by a shared Limiter. It's the concurrent
+// sibling of SequenceOperation: planners can mix the two, e.g.
+// Sequence{ Parallel{ RunContainer×N }, Parallel{ StopContainer×N } }, so a rolling deploy of N
+// replicas takes O(N/MaxConcurrency) wall time instead of O(N).
+type ParallelOperation struct {
+	Operations []Operation
+	// MaxConcurrency bounds how many operations run at once. Zero or negative means unbounded.
+	MaxConcurrency int
+	// Limiter, if set, is acquired before each operation executes. It's typically shared across
+	// several ParallelOperations (e.g. image pulls and container creates across many machines) so
+	// a large fan-out doesn't overwhelm the Docker daemon on any one machine.
+	Limiter *rate.Limiter
+
+	completedMu sync.Mutex
+	// completed holds the children that may need compensating: every child that executed
+	// successfully, plus any child that failed but implements CompensatingOperation itself (e.g. a
+	// RunContainerOperation that created and started a container before failing its health
+	// check - its Compensate() is written to handle exactly this partially-executed case). A
+	// sibling failure cancels the rest but doesn't undo these, so Compensate needs them to build
+	// the rollback operation for this ParallelOperation as a whole.
+	completed []Operation
+}
+
+func (o *ParallelOperation) Execute(ctx context.Context, cli Client) (err error) {
+	ctx, _ = ensureOperationID(ctx)
+	notifyStart(ctx, o)
+	defer func() { notifyComplete(ctx, o, err) }()
+
+	g, gctx := errgroup.WithContext(ctx)
+	if o.MaxConcurrency > 0 {
+		g.SetLimit(o.MaxConcurrency)
+	}
+
+	for _, op := range o.Operations {
+		g.Go(func() error {
+			if o.Limiter != nil {
+				if err := o.Limiter.Wait(gctx); err != nil {
+					return err
+				}
+			}
+			if err := op.Execute(gctx, cli); err != nil {
+				if _, ok := op.(CompensatingOperation); ok {
+					o.recordCompensable(op)
+				}
+				return err
+			}
+
+			o.recordCompensable(op)
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// recordCompensable appends op to o.completed under o.completedMu, safe to call concurrently from
+// the goroutines Execute spawns per child operation.
+func (o *ParallelOperation) recordCompensable(op Operation) {
+	o.completedMu.Lock()
+	o.completed = append(o.completed, op)
+	o.completedMu.Unlock()
+}
+
+// Compensate returns a ParallelOperation that undoes every completed or partially-executed child
+// recorded in o.completed, so a SequenceOperation rolling back a plan that already ran a
+// ParallelOperation doesn't leave that group's effects un-compensated. It returns nil if none of
+// those children implement CompensatingOperation themselves, or none of them had anything to
+// undo.
+func (o *ParallelOperation) Compensate() Operation {
+	o.completedMu.Lock()
+	completed := append([]Operation(nil), o.completed...)
+	o.completedMu.Unlock()
+
+	var inverses []Operation
+	for i := len(completed) - 1; i >= 0; i-- {
+		comp, ok := completed[i].(CompensatingOperation)
+		if !ok {
+			continue
+		}
+		if inverse := comp.Compensate(); inverse != nil {
+			inverses = append(inverses, inverse)
+		}
+	}
+	if len(inverses) == 0 {
+		return nil
+	}
+
+	return &ParallelOperation{
+		Operations:     inverses,
+		MaxConcurrency: o.MaxConcurrency,
+		Limiter:        o.Limiter,
+	}
+}
+
+func (o *ParallelOperation) Format(resolver NameResolver) string {
+	ops := make([]string, len(o.Operations))
+	for i, op := range o.Operations {
+		ops[i] = formatOperation(op, resolver)
+	}
+
+	return fmt.Sprintf("Parallel [max_concurrency=%d]:\n%s", o.MaxConcurrency, strings.Join(ops, "\n"))
+}
+
+func (o *ParallelOperation) String() string {
+	ops := make([]string, len(o.Operations))
+	for i, op := range o.Operations {
+		ops[i] = op.String()
+	}
+
+	return fmt.Sprintf("ParallelOperation[max_concurrency=%d operations=%s]",
+		o.MaxConcurrency, strings.Join(ops, ", "))
+}
@@ -0,0 +1,194 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeOperation is a minimal Operation test double that appends its name to a shared log when
+// executed, optionally failing, and optionally compensating via undo.
+type fakeOperation struct {
+	name     string
+	failWith error
+	undo     *fakeOperation
+	log      *[]string
+}
+
+func (f *fakeOperation) Execute(_ context.Context, _ Client) error {
+	*f.log = append(*f.log, f.name)
+	return f.failWith
+}
+
+func (f *fakeOperation) Format(_ NameResolver) string { return f.name }
+func (f *fakeOperation) String() string               { return f.name }
+
+// compensatingOperation wraps fakeOperation so only operations that are meant to support
+// compensation implement CompensatingOperation, letting tests exercise the "doesn't implement it
+// at all" path too.
+type compensatingOperation struct {
+	*fakeOperation
+}
+
+func (c *compensatingOperation) Compensate() Operation {
+	if c.undo == nil {
+		return nil
+	}
+	return &compensatingOperation{fakeOperation: c.undo}
+}
+
+func TestSequenceOperation_RollbackOrdering(t *testing.T) {
+	var log []string
+
+	first := &compensatingOperation{&fakeOperation{name: "first", log: &log, undo: &fakeOperation{name: "undo-first", log: &log}}}
+	second := &compensatingOperation{&fakeOperation{name: "second", log: &log, undo: &fakeOperation{name: "undo-second", log: &log}}}
+	third := &fakeOperation{name: "third", log: &log, failWith: errors.New("boom")}
+
+	seq := &SequenceOperation{
+		Operations:     []Operation{first, second, third},
+		RollbackPolicy: RollbackAlways,
+	}
+
+	err := seq.Execute(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected the sequence to return third's error")
+	}
+
+	want := []string{"first", "second", "third", "undo-second", "undo-first"}
+	if len(log) != len(want) {
+		t.Fatalf("execution log = %v, want %v", log, want)
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Fatalf("execution log = %v, want %v", log, want)
+		}
+	}
+}
+
+func TestSequenceOperation_RollbackCompensatesTheFailingOperationItself(t *testing.T) {
+	var log []string
+
+	// first succeeds and is compensable like any other completed operation.
+	first := &compensatingOperation{&fakeOperation{name: "first", log: &log, undo: &fakeOperation{name: "undo-first", log: &log}}}
+	// second fails its own Execute (e.g. a RunContainerOperation whose container was created and
+	// started but then failed its health check), yet still implements CompensatingOperation and
+	// has something to undo.
+	second := &compensatingOperation{&fakeOperation{
+		name:     "second",
+		log:      &log,
+		failWith: errors.New("boom"),
+		undo:     &fakeOperation{name: "undo-second", log: &log},
+	}}
+
+	seq := &SequenceOperation{
+		Operations:     []Operation{first, second},
+		RollbackPolicy: RollbackAlways,
+	}
+
+	err := seq.Execute(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected the sequence to return second's error")
+	}
+
+	want := []string{"first", "second", "undo-second", "undo-first"}
+	if len(log) != len(want) {
+		t.Fatalf("execution log = %v, want %v", log, want)
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Fatalf("execution log = %v, want %v", log, want)
+		}
+	}
+}
+
+func TestSequenceOperation_RollbackIncompensableReportsError(t *testing.T) {
+	var log []string
+
+	// first implements only Operation, not CompensatingOperation, to exercise rollback()'s
+	// loud-failure path for a composite it already ran but can't undo.
+	first := &fakeOperation{name: "first", log: &log}
+	second := &fakeOperation{name: "second", log: &log, failWith: errors.New("boom")}
+
+	seq := &SequenceOperation{
+		Operations:     []Operation{first, second},
+		RollbackPolicy: RollbackAlways,
+	}
+
+	err := seq.Execute(context.Background(), nil)
+	if err == nil || !errors.Is(err, second.failWith) {
+		t.Fatalf("error = %v, want it to wrap %v", err, second.failWith)
+	}
+	if !strings.Contains(err.Error(), "rollback incomplete") {
+		t.Fatalf("error = %v, want it to report that first could not be compensated", err)
+	}
+}
+
+func TestParallelOperation_CompensateUndoesOnlyCompletedChildren(t *testing.T) {
+	var log []string
+
+	ok1 := &compensatingOperation{&fakeOperation{name: "ok1", log: &log, undo: &fakeOperation{name: "undo-ok1", log: &log}}}
+	ok2 := &compensatingOperation{&fakeOperation{name: "ok2", log: &log, undo: &fakeOperation{name: "undo-ok2", log: &log}}}
+	failing := &fakeOperation{name: "failing", log: &log, failWith: errors.New("boom")}
+
+	par := &ParallelOperation{Operations: []Operation{ok1, ok2, failing}}
+	if err := par.Execute(context.Background(), nil); err == nil {
+		t.Fatal("expected the parallel group to return failing's error")
+	}
+
+	inverse := par.Compensate()
+	if inverse == nil {
+		t.Fatal("expected Compensate to return an operation undoing the completed children")
+	}
+	if err := inverse.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("compensate execute: %v", err)
+	}
+
+	for _, want := range []string{"undo-ok1", "undo-ok2"} {
+		found := false
+		for _, got := range log {
+			if got == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("execution log = %v, missing %q", log, want)
+		}
+	}
+}
+
+func TestParallelOperation_CompensateIncludesTheFailingChildItself(t *testing.T) {
+	var log []string
+
+	// failing fails its own Execute but, like a RunContainerOperation whose container started
+	// before its health check failed, still has something to undo.
+	failing := &compensatingOperation{&fakeOperation{
+		name:     "failing",
+		log:      &log,
+		failWith: errors.New("boom"),
+		undo:     &fakeOperation{name: "undo-failing", log: &log},
+	}}
+
+	par := &ParallelOperation{Operations: []Operation{failing}}
+	if err := par.Execute(context.Background(), nil); err == nil {
+		t.Fatal("expected the parallel group to return failing's error")
+	}
+
+	inverse := par.Compensate()
+	if inverse == nil {
+		t.Fatal("expected Compensate to return an operation undoing the failing child")
+	}
+	if err := inverse.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("compensate execute: %v", err)
+	}
+
+	found := false
+	for _, got := range log {
+		if got == "undo-failing" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("execution log = %v, missing %q", log, "undo-failing")
+	}
+}